@@ -1,140 +1,109 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
+	"strings"
 
-	"golang.org/x/oauth2/google"
+	"github.com/freddd/scripts/gcpclient"
+	"github.com/freddd/scripts/iamscan"
 )
 
-var PERMISSIONS_TO_CHECK = []string{
-	"cloudfunctions.functions.call",
-	"cloudfunctions.functions.invoke",
-	"cloudfunctions.functions.delete",
-	"cloudfunctions.functions.get",
-	"cloudfunctions.functions.update",
-	"cloudfunctions.functions.sourceCodeGet",
-	"cloudfunctions.functions.sourceCodeSet",
-	"cloudfunctions.functions.getIamPolicy",
-	"cloudfunctions.functions.setIamPolicy",
-	"cloudfunctions.operations.get",
-	"cloudfunctions.operations.list",
-}
-
-type permissionsRequest struct {
-	Permissions []string `json:"permissions"`
-}
-
-type permissionsResponse struct {
-	Permissions []string `json:"permissions"`
-}
-
-func performPermissionCheck(url string, payload []byte, token string) {
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	if err != nil {
-		log.Printf("❌ Failed to create request: %v", err)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("An error occurred with the network request: %v", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("❌ Failed to read response body: %v", err)
-		return
-	}
+func printTextReport(results []iamscan.Result) {
+	for _, r := range results {
+		fmt.Printf("\n==================== %s: %s/%s ====================\n", r.Target.Kind, r.Target.Project, r.Target.Name)
 
-	if resp.StatusCode == http.StatusOK {
-		var respJSON permissionsResponse
-		if err := json.Unmarshal(body, &respJSON); err != nil {
-			log.Printf("❌ Failed to parse JSON response: %v", err)
-			return
+		if r.Error != "" {
+			fmt.Printf("❌ %s\n", r.Error)
+			continue
 		}
 
-		grantedPermissions := make(map[string]bool)
-		for _, p := range respJSON.Permissions {
-			grantedPermissions[p] = true
+		granted := make(map[string]bool)
+		for _, p := range r.AuthenticatedGranted {
+			granted[p] = true
+		}
+		for _, p := range r.AnonymousGranted {
+			granted[p] = true
 		}
 
-		fmt.Println("✅ = Granted, ❌ = Not Granted\n")
-		for _, permission := range PERMISSIONS_TO_CHECK {
-			if grantedPermissions[permission] {
-				fmt.Printf("✅ %s\n", permission)
-			} else {
-				fmt.Printf("❌ %s\n", permission)
+		fmt.Println("✅ = Granted, ❌ = Not Granted")
+		for _, permission := range r.CheckedPermissions {
+			mark := "❌"
+			if granted[permission] {
+				mark = "✅"
 			}
+			fmt.Printf("%s %s\n", mark, permission)
 		}
+
 		fmt.Println("------------------------------------------------------------")
-		fmt.Printf("Found %d granted permissions out of %d checked.\n", len(grantedPermissions), len(PERMISSIONS_TO_CHECK))
-	} else {
-		fmt.Printf("ℹ️  Request failed with Status Code: %d\n", resp.StatusCode)
-		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-			fmt.Println("   This is expected for unauthenticated users if the function is not public.")
-		} else {
-			fmt.Printf("   Response: %s\n", string(body))
+		fmt.Printf("Found %d granted permissions out of %d checked.\n", len(granted), len(r.CheckedPermissions))
+		if len(r.PubliclyExposed) > 0 {
+			fmt.Printf("⚠️  Publicly exposed (no auth required): %s\n", strings.Join(r.PubliclyExposed, ", "))
 		}
 	}
 }
 
 func main() {
-	projectID := flag.String("project-id", "", "Your Google Cloud project ID. (Required)")
-	location := flag.String("location", "", "The location/region of the Cloud Function (e.g., us-central1). (Required)")
-	functionName := flag.String("function-name", "", "The name of the Cloud Function. (Required)")
+	projectID := flag.String("project-id", "", "Your Google Cloud project ID.")
+	location := flag.String("location", "", "The location/region of the resource (e.g., us-central1).")
+	functionName := flag.String("function-name", "", "The name of the Cloud Function to scan.")
+	resourcesFile := flag.String("resources-file", "", "Path to a JSON file of {kind, project, location, name} targets. Overrides --project-id/--location/--function-name.")
+	workers := flag.Int("workers", 10, "Number of resources to scan concurrently.")
+	format := flag.String("format", "text", "Output format: text, json, csv, or sarif.")
+	verbose := flag.Bool("verbose", false, "Log every outgoing request and response status.")
 
 	flag.Parse()
 
-	if *projectID == "" || *location == "" || *functionName == "" {
-		fmt.Println("❌ Missing required flags: --project-id, --location, and --function-name are required.")
-		flag.Usage()
-		os.Exit(1)
+	var targets []iamscan.Target
+	if *resourcesFile != "" {
+		loaded, err := iamscan.LoadTargets(*resourcesFile)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		targets = loaded
+	} else {
+		if *projectID == "" || *location == "" || *functionName == "" {
+			fmt.Println("❌ Missing required flags: --project-id, --location, and --function-name are required (or pass --resources-file).")
+			flag.Usage()
+			os.Exit(1)
+		}
+		targets = []iamscan.Target{{
+			Kind:     "cloudfunction",
+			Project:  *projectID,
+			Location: *location,
+			Name:     *functionName,
+		}}
 	}
 
-	resource := fmt.Sprintf("projects/%s/locations/%s/functions/%s", *projectID, *location, *functionName)
-	url := fmt.Sprintf("https://cloudfunctions.googleapis.com/v1/%s:testIamPermissions", resource)
+	fmt.Printf("▶️  Scanning %d resource(s) with %d worker(s)...\n", len(targets), *workers)
 
-	reqPayload := permissionsRequest{Permissions: PERMISSIONS_TO_CHECK}
-	payloadBytes, err := json.Marshal(reqPayload)
+	client, err := gcpclient.New(context.Background(), []string{"https://www.googleapis.com/auth/cloud-platform"}, *verbose)
 	if err != nil {
-		log.Fatalf("❌ Failed to create request JSON: %v", err)
+		fmt.Println("ℹ️  Could not obtain ADC credentials; authenticated checks will be skipped.")
+		fmt.Println("   Run 'gcloud auth application-default login' to enable them.")
+		client = gcpclient.NewUnauthenticated(*verbose)
 	}
 
-	fmt.Printf("▶️  Targeting function: '%s' in project '%s'...\n", *functionName, *projectID)
-
-	fmt.Println("\n" + "==================== UNAUTHENTICATED CHECK ====================")
-	performPermissionCheck(url, payloadBytes, "")
+	scanner := iamscan.NewScanner(client, *workers)
+	results := scanner.Scan(targets)
 
-	fmt.Println("\n" + "===================== AUTHENTICATED CHECK =====================")
-	ctx := context.Background()
-	tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		fmt.Println("❌ Error: Could not get authentication credentials for the authenticated check.")
-		fmt.Println("   Please run 'gcloud auth application-default login'.")
-		fmt.Printf("   Details: %v\n", err)
-		return
-	}
-
-	token, err := tokenSource.Token()
-	if err != nil {
-		fmt.Printf("❌ Error: Could not retrieve token from credentials: %v\n", err)
-		return
+	switch *format {
+	case "json":
+		if err := iamscan.WriteJSON(os.Stdout, results); err != nil {
+			log.Fatalf("❌ Failed to write JSON output: %v", err)
+		}
+	case "csv":
+		if err := iamscan.WriteCSV(os.Stdout, results); err != nil {
+			log.Fatalf("❌ Failed to write CSV output: %v", err)
+		}
+	case "sarif":
+		if err := iamscan.WriteSARIF(os.Stdout, results); err != nil {
+			log.Fatalf("❌ Failed to write SARIF output: %v", err)
+		}
+	default:
+		printTextReport(results)
 	}
-	performPermissionCheck(url, payloadBytes, token.AccessToken)
 }
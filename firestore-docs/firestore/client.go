@@ -0,0 +1,207 @@
+package firestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/freddd/scripts/gcpclient"
+)
+
+// Document mirrors the REST representation of a Firestore document.
+type Document struct {
+	Name       string         `json:"name"`
+	Fields     map[string]any `json:"fields"`
+	CreateTime string         `json:"createTime"`
+	UpdateTime string         `json:"updateTime"`
+}
+
+// Client wraps the Firestore REST API for a single project/database over a gcpclient,
+// so auth, retries and logging stay consistent with the repo's other GCP tools.
+type Client struct {
+	APIKey     string
+	ProjectID  string
+	DatabaseID string
+	GCP        *gcpclient.Client
+}
+
+// NewClient builds a Client. databaseID is usually "(default)".
+func NewClient(apiKey, projectID, databaseID string, gcp *gcpclient.Client) *Client {
+	return &Client{APIKey: apiKey, ProjectID: projectID, DatabaseID: databaseID, GCP: gcp}
+}
+
+func (c *Client) baseURL() string {
+	return fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/%s", c.ProjectID, c.DatabaseID)
+}
+
+func (c *Client) withKey(url string) string {
+	if strings.Contains(url, "?") {
+		return url + "&key=" + c.APIKey
+	}
+	return url + "?key=" + c.APIKey
+}
+
+func (c *Client) do(method, url string, body io.Reader) (*gcpclient.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.GCP.Do(req)
+}
+
+func decode(resp *gcpclient.Response, out any) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(resp.Body))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+	return nil
+}
+
+// Get fetches a single document at documentPath (e.g. "users/user123") using ADC
+// credentials.
+func (c *Client) Get(documentPath string) (*Document, error) {
+	url := c.withKey(fmt.Sprintf("%s/documents/%s", c.baseURL(), documentPath))
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := decode(resp, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetDual fetches a single document both anonymously and authenticated, so the two can be
+// compared to spot documents that are readable without credentials.
+func (c *Client) GetDual(documentPath string) (*gcpclient.DualResult, error) {
+	url := c.withKey(fmt.Sprintf("%s/documents/%s", c.baseURL(), documentPath))
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.GCP.DualCheck(req)
+}
+
+// ParseDocument decodes a gcpclient.Response from either leg of GetDual into a Document.
+func ParseDocument(resp *gcpclient.Response) (*Document, error) {
+	var doc Document
+	if err := decode(resp, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// List paginates through every document directly under collectionPath.
+func (c *Client) List(collectionPath string, pageSize int) ([]Document, error) {
+	var all []Document
+	pageToken := ""
+
+	for {
+		url := fmt.Sprintf("%s/documents/%s?pageSize=%d", c.baseURL(), collectionPath, pageSize)
+		if pageToken != "" {
+			url += "&pageToken=" + pageToken
+		}
+
+		resp, err := c.do("GET", c.withKey(url), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Documents     []Document `json:"documents"`
+			NextPageToken string     `json:"nextPageToken"`
+		}
+		if err := decode(resp, &page); err != nil {
+			return nil, err
+		}
+
+		all = append(all, page.Documents...)
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return all, nil
+}
+
+// ListCollectionIDs returns the collection IDs directly under parentPath (empty for the
+// database root).
+func (c *Client) ListCollectionIDs(parentPath string) ([]string, error) {
+	url := c.baseURL() + "/documents"
+	if parentPath != "" {
+		url += "/" + parentPath
+	}
+	url += ":listCollectionIds"
+	resp, err := c.do("POST", c.withKey(url), strings.NewReader("{}"))
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		CollectionIDs []string `json:"collectionIds"`
+	}
+	if err := decode(resp, &parsed); err != nil {
+		return nil, err
+	}
+	return parsed.CollectionIDs, nil
+}
+
+// Query runs a Firestore StructuredQuery (as raw JSON) rooted at parentPath (empty for the
+// database root) and returns every document the query matched.
+func (c *Client) Query(parentPath string, structuredQuery json.RawMessage) ([]Document, error) {
+	url := c.baseURL() + "/documents"
+	if parentPath != "" {
+		url += "/" + parentPath
+	}
+	url += ":runQuery"
+
+	payload, err := json.Marshal(struct {
+		StructuredQuery json.RawMessage `json:"structuredQuery"`
+	}{StructuredQuery: structuredQuery})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode structured query: %w", err)
+	}
+
+	resp, err := c.do("POST", c.withKey(url), strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Document *Document `json:"document"`
+	}
+	if err := decode(resp, &entries); err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Document != nil {
+			docs = append(docs, *entry.Document)
+		}
+	}
+	return docs, nil
+}
+
+// relativeDocPath strips the "projects/.../databases/.../documents/" prefix from a
+// document's fully-qualified resource name, leaving the path usable with List/Get/Query.
+func relativeDocPath(name string) string {
+	_, rel, found := strings.Cut(name, "/documents/")
+	if !found {
+		return name
+	}
+	return rel
+}
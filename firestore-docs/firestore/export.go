@@ -0,0 +1,60 @@
+package firestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Export recursively walks the document tree rooted at collectionPath (empty for the
+// database root) and writes one NDJSON record per document, decoding fields with
+// ParseFields along the way.
+func (c *Client) Export(collectionPath string, typed bool, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	if collectionPath != "" {
+		return c.exportCollection(collectionPath, typed, enc)
+	}
+
+	rootCollections, err := c.ListCollectionIDs("")
+	if err != nil {
+		return fmt.Errorf("failed to list root collections: %w", err)
+	}
+	for _, id := range rootCollections {
+		if err := c.exportCollection(id, typed, enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) exportCollection(collectionPath string, typed bool, enc *json.Encoder) error {
+	docs, err := c.List(collectionPath, 100)
+	if err != nil {
+		return fmt.Errorf("failed to list %q: %w", collectionPath, err)
+	}
+
+	for _, doc := range docs {
+		record := map[string]any{
+			"name":       doc.Name,
+			"createTime": doc.CreateTime,
+			"updateTime": doc.UpdateTime,
+			"fields":     ParseFields(doc.Fields, typed),
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write record for %q: %w", doc.Name, err)
+		}
+
+		docPath := relativeDocPath(doc.Name)
+		subCollections, err := c.ListCollectionIDs(docPath)
+		if err != nil {
+			return fmt.Errorf("failed to list subcollections of %q: %w", docPath, err)
+		}
+		for _, sub := range subCollections {
+			if err := c.exportCollection(docPath+"/"+sub, typed, enc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
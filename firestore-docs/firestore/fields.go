@@ -0,0 +1,90 @@
+package firestore
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// ParseFields decodes a Firestore REST "fields" map into plain Go values. Integers are
+// decoded from Firestore's wire string into an int64. When typed is true, values that
+// would otherwise lose type information on the round trip (timestamps, references, geo
+// points, bytes, and integers — whose wire string can exceed what an int64-consuming
+// caller round-trips exactly) are wrapped in a `{"$type": "...", "value": ...}` envelope
+// instead, keeping the original wire value so the result can be marshaled back into
+// Firestore losslessly.
+func ParseFields(fields map[string]any, typed bool) map[string]any {
+	parsed := make(map[string]any)
+	if fields == nil {
+		return parsed
+	}
+	for key, value := range fields {
+		parsed[key] = parseValue(value, typed)
+	}
+	return parsed
+}
+
+func parseValue(value any, typed bool) any {
+	valueDict, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	for valueType, actual := range valueDict {
+		switch valueType {
+		case "nullValue":
+			return nil
+		case "stringValue", "doubleValue", "booleanValue":
+			return actual
+		case "integerValue":
+			encoded, _ := actual.(string)
+			if typed {
+				return typedEnvelope(typed, "integer", encoded)
+			}
+			decoded, err := strconv.ParseInt(encoded, 10, 64)
+			if err != nil {
+				return encoded
+			}
+			return decoded
+		case "timestampValue":
+			return typedEnvelope(typed, "timestamp", actual)
+		case "referenceValue":
+			return typedEnvelope(typed, "reference", actual)
+		case "bytesValue":
+			encoded, _ := actual.(string)
+			if typed {
+				return typedEnvelope(typed, "bytes", encoded)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return encoded
+			}
+			return decoded
+		case "geoPointValue":
+			point, _ := actual.(map[string]any)
+			geo := map[string]any{"lat": point["latitude"], "lng": point["longitude"]}
+			return typedEnvelope(typed, "geopoint", geo)
+		case "mapValue":
+			mapValue, _ := actual.(map[string]any)
+			nestedFields, _ := mapValue["fields"].(map[string]any)
+			return ParseFields(nestedFields, typed)
+		case "arrayValue":
+			arrayValue, _ := actual.(map[string]any)
+			values, _ := arrayValue["values"].([]any)
+			out := make([]any, 0, len(values))
+			for _, v := range values {
+				out = append(out, parseValue(v, typed))
+			}
+			return out
+		default:
+			return actual
+		}
+	}
+	return nil
+}
+
+func typedEnvelope(typed bool, kind string, value any) any {
+	if !typed {
+		return value
+	}
+	return map[string]any{"$type": kind, "value": value}
+}
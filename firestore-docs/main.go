@@ -1,143 +1,234 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
+
+	fs "github.com/freddd/scripts/firestore-docs/firestore"
+	"github.com/freddd/scripts/gcpclient"
 )
 
-type FirestoreDocument struct {
-	Name       string         `json:"name"`
-	Fields     map[string]any `json:"fields"`
-	CreateTime string         `json:"createTime"`
-	UpdateTime string         `json:"updateTime"`
+var subcommands = map[string]bool{
+	"get":              true,
+	"list":             true,
+	"list-collections": true,
+	"query":            true,
+	"export":           true,
 }
 
-func parseFirestoreFields(fields map[string]any) map[string]any {
-	parsedData := make(map[string]any)
-	if fields == nil {
-		return parsedData
+func main() {
+	cmd := "get"
+	args := os.Args[1:]
+	if len(os.Args) > 1 && subcommands[os.Args[1]] {
+		cmd = os.Args[1]
+		args = os.Args[2:]
 	}
 
-	for key, value := range fields {
-		valueDict, ok := value.(map[string]any)
-		if !ok {
-			parsedData[key] = value
-			continue
-		}
+	switch cmd {
+	case "get":
+		runGet(args)
+	case "list":
+		runList(args)
+	case "list-collections":
+		runListCollections(args)
+	case "query":
+		runQuery(args)
+	case "export":
+		runExport(args)
+	}
+}
 
-		for valueType, actualValue := range valueDict {
-			if valueType == "mapValue" {
-				mapValue, ok := actualValue.(map[string]any)
-				if ok {
-					nestedFields, ok := mapValue["fields"].(map[string]any)
-					if ok {
-						parsedData[key] = parseFirestoreFields(nestedFields)
-					}
-				}
-			} else {
-				parsedData[key] = actualValue
-			}
-			break
-		}
+func commonFlags(fset *flag.FlagSet) (apiKey, projectID, databaseID *string, verbose *bool) {
+	apiKey = fset.String("api-key", "", "Google Cloud API key. (Required)")
+	projectID = fset.String("project-id", "", "Your Google Cloud project ID. (Required)")
+	databaseID = fset.String("database-id", "(default)", "The Firestore database ID (usually '(default)').")
+	verbose = fset.Bool("verbose", false, "Log every outgoing request and response status.")
+	return
+}
+
+// newClient builds an ADC-authenticated gcpclient, falling back to an unauthenticated one
+// (with a printed hint) when credentials aren't available.
+func newClient(verbose bool) *gcpclient.Client {
+	client, err := gcpclient.New(context.Background(), []string{"https://www.googleapis.com/auth/cloud-platform"}, verbose)
+	if err != nil {
+		fmt.Println("ℹ️  Could not obtain ADC credentials; run 'gcloud auth application-default login' to enable authenticated checks.")
+		return gcpclient.NewUnauthenticated(verbose)
 	}
-	return parsedData
+	return client
 }
 
-func performFirestoreGet(url string, token string) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
+func printFields(parsed map[string]any) {
+	prettyFields, err := json.MarshalIndent(parsed, "", "    ")
 	if err != nil {
-		log.Printf("❌ Failed to create request: %v", err)
-		return
+		log.Fatalf("❌ Failed to format parsed fields as JSON: %v", err)
 	}
+	fmt.Println(string(prettyFields))
+}
+
+func runGet(args []string) {
+	fset := flag.NewFlagSet("get", flag.ExitOnError)
+	apiKey, projectID, databaseID, verbose := commonFlags(fset)
+	documentPath := fset.String("document-path", "", "The full path to the document (e.g., 'users/user123'). (Required)")
+	typed := fset.Bool("typed", false, "Emit {\"$type\":...} envelopes for lossless round-tripping.")
+	fset.Parse(args)
 
-	req.Header.Set("Accept", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	if *apiKey == "" || *projectID == "" || *documentPath == "" {
+		fmt.Println("❌ Missing required flags: --api-key, --project-id, and --document-path are required.")
+		fset.Usage()
+		os.Exit(1)
 	}
 
-	resp, err := client.Do(req)
+	fmt.Printf("▶️  Targeting Firestore document: '%s'\n", *documentPath)
+	client := fs.NewClient(*apiKey, *projectID, *databaseID, newClient(*verbose))
+
+	dual, err := client.GetDual(*documentPath)
 	if err != nil {
-		log.Printf("An error occurred with the network request: %v", err)
-		return
+		log.Fatalf("❌ %v", err)
+	}
+
+	fmt.Println("\n==================== UNAUTHENTICATED GET =====================")
+	reportGet(dual.Anonymous, *typed)
+
+	fmt.Println("\n===================== AUTHENTICATED GET ======================")
+	if dual.Authenticated != nil {
+		reportGet(dual.Authenticated, *typed)
+	} else {
+		fmt.Println("ℹ️  No ADC credentials available. Skipping authenticated check.")
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
+func reportGet(resp *gcpclient.Response, typed bool) {
+	doc, err := fs.ParseDocument(resp)
 	if err != nil {
-		log.Printf("❌ Failed to read response body: %v", err)
+		fmt.Printf("❌ Request failed: %v\n", err)
 		return
 	}
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		fmt.Println("✅ Request successful (200 OK). Document found and is readable.")
-		var doc FirestoreDocument
-		if err := json.Unmarshal(body, &doc); err != nil {
-			log.Printf("❌ Failed to parse JSON response: %v", err)
-			return
-		}
+	fmt.Println("✅ Request successful. Document found and is readable.")
+	fmt.Printf("   - Name: %s\n", doc.Name)
+	fmt.Printf("   - Create Time: %s\n", doc.CreateTime)
+	fmt.Printf("   - Update Time: %s\n", doc.UpdateTime)
+	fmt.Println("   - Fields (parsed):")
+	printFields(fs.ParseFields(doc.Fields, typed))
+}
 
-		fmt.Printf("   - Name: %s\n", doc.Name)
-		fmt.Printf("   - Create Time: %s\n", doc.CreateTime)
-		fmt.Printf("   - Update Time: %s\n", doc.UpdateTime)
+func runList(args []string) {
+	fset := flag.NewFlagSet("list", flag.ExitOnError)
+	apiKey, projectID, databaseID, verbose := commonFlags(fset)
+	collectionPath := fset.String("collection-path", "", "The collection to list (e.g., 'users'). (Required)")
+	pageSize := fset.Int("page-size", 100, "Number of documents to request per page.")
+	typed := fset.Bool("typed", false, "Emit {\"$type\":...} envelopes for lossless round-tripping.")
+	fset.Parse(args)
+
+	if *apiKey == "" || *projectID == "" || *collectionPath == "" {
+		fmt.Println("❌ Missing required flags: --api-key, --project-id, and --collection-path are required.")
+		fset.Usage()
+		os.Exit(1)
+	}
 
-		fmt.Println("   - Fields (parsed):")
-		parsedFields := parseFirestoreFields(doc.Fields)
-		prettyFields, err := json.MarshalIndent(parsedFields, "", "    ")
-		if err != nil {
-			log.Printf("❌ Failed to format parsed fields as JSON: %v", err)
-			return
-		}
-		fmt.Println(string(prettyFields))
-
-	case http.StatusNotFound:
-		fmt.Println("ℹ️  Request failed (404 Not Found). The document does not exist at this path.")
-	case http.StatusUnauthorized, http.StatusForbidden:
-		fmt.Printf("❌ Request failed (%d Permission Denied).\n", resp.StatusCode)
-		if token == "" {
-			fmt.Println("   This is the expected result for an unauthenticated user on a protected database.")
-		} else {
-			fmt.Println("   The provided token may be invalid, expired, or lack the required permissions.")
-		}
-	default:
-		fmt.Printf("❌ An error occurred. Status Code: %d\n", resp.StatusCode)
-		fmt.Printf("   Response: %s\n", string(body))
+	client := fs.NewClient(*apiKey, *projectID, *databaseID, newClient(*verbose))
+	docs, err := client.List(*collectionPath, *pageSize)
+	if err != nil {
+		log.Fatalf("❌ Failed to list '%s': %v", *collectionPath, err)
+	}
+
+	fmt.Printf("✅ Found %d document(s) under '%s'.\n", len(docs), *collectionPath)
+	for _, doc := range docs {
+		fmt.Printf("\n- %s\n", doc.Name)
+		printFields(fs.ParseFields(doc.Fields, *typed))
 	}
 }
 
-func main() {
-	apiKey := flag.String("api-key", "", "Google Cloud API key. (Required)")
-	projectID := flag.String("project-id", "", "Your Google Cloud project ID. (Required)")
-	documentPath := flag.String("document-path", "", "The full path to the document (e.g., 'users/user123'). (Required)")
-	databaseID := flag.String("database-id", "(default)", "The Firestore database ID (usually '(default)').")
-	token := flag.String("token", "", "Optional. An OAuth 2.0 bearer token for authenticated requests.")
+func runListCollections(args []string) {
+	fset := flag.NewFlagSet("list-collections", flag.ExitOnError)
+	apiKey, projectID, databaseID, verbose := commonFlags(fset)
+	parentPath := fset.String("parent-path", "", "Optional. Document path whose subcollections to list (empty lists root-level collections).")
+	fset.Parse(args)
 
-	flag.Parse()
+	if *apiKey == "" || *projectID == "" {
+		fmt.Println("❌ Missing required flags: --api-key and --project-id are required.")
+		fset.Usage()
+		os.Exit(1)
+	}
 
-	if *apiKey == "" || *projectID == "" || *documentPath == "" {
-		fmt.Println("❌ Missing required flags: --api-key, --project-id, and --document-path are required.")
-		flag.Usage()
+	client := fs.NewClient(*apiKey, *projectID, *databaseID, newClient(*verbose))
+	collectionIDs, err := client.ListCollectionIDs(*parentPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to list collections under '%s': %v", *parentPath, err)
+	}
+
+	fmt.Printf("✅ Found %d collection(s):\n", len(collectionIDs))
+	for _, id := range collectionIDs {
+		fmt.Printf("   - %s\n", id)
+	}
+}
+
+func runQuery(args []string) {
+	fset := flag.NewFlagSet("query", flag.ExitOnError)
+	apiKey, projectID, databaseID, verbose := commonFlags(fset)
+	parentPath := fset.String("parent-path", "", "Optional. Collection/document the query is rooted at.")
+	queryFile := fset.String("query-file", "", "Path to a JSON file containing a Firestore StructuredQuery. (Required)")
+	typed := fset.Bool("typed", false, "Emit {\"$type\":...} envelopes for lossless round-tripping.")
+	fset.Parse(args)
+
+	if *apiKey == "" || *projectID == "" || *queryFile == "" {
+		fmt.Println("❌ Missing required flags: --api-key, --project-id, and --query-file are required.")
+		fset.Usage()
 		os.Exit(1)
 	}
 
-	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/%s/documents/%s?key=%s", *projectID, *databaseID, *documentPath, *apiKey)
+	raw, err := os.ReadFile(*queryFile)
+	if err != nil {
+		log.Fatalf("❌ Failed to read --query-file: %v", err)
+	}
+
+	client := fs.NewClient(*apiKey, *projectID, *databaseID, newClient(*verbose))
+	docs, err := client.Query(*parentPath, json.RawMessage(raw))
+	if err != nil {
+		log.Fatalf("❌ Query failed: %v", err)
+	}
 
-	fmt.Printf("▶️  Targeting Firestore document: '%s'\n", *documentPath)
-	fmt.Printf("   API Endpoint: %s\n", url)
+	fmt.Printf("✅ Query matched %d document(s).\n", len(docs))
+	for _, doc := range docs {
+		fmt.Printf("\n- %s\n", doc.Name)
+		printFields(fs.ParseFields(doc.Fields, *typed))
+	}
+}
 
-	fmt.Println("\n" + "==================== UNAUTHENTICATED GET =====================")
-	performFirestoreGet(url, "")
+func runExport(args []string) {
+	fset := flag.NewFlagSet("export", flag.ExitOnError)
+	apiKey, projectID, databaseID, verbose := commonFlags(fset)
+	collectionPath := fset.String("collection-path", "", "Optional. Root collection to export from (empty exports the whole database).")
+	out := fset.String("out", "", "Path to write NDJSON output to. Defaults to stdout.")
+	typed := fset.Bool("typed", false, "Emit {\"$type\":...} envelopes for lossless round-tripping.")
+	fset.Parse(args)
+
+	if *apiKey == "" || *projectID == "" {
+		fmt.Println("❌ Missing required flags: --api-key and --project-id are required.")
+		fset.Usage()
+		os.Exit(1)
+	}
 
-	fmt.Println("\n" + "===================== AUTHENTICATED GET ======================")
-	if *token != "" {
-		performFirestoreGet(url, *token)
-	} else {
-		fmt.Println("ℹ️  No --token provided. Skipping authenticated check.")
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("❌ Failed to create --out file: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	client := fs.NewClient(*apiKey, *projectID, *databaseID, newClient(*verbose))
+	if err := client.Export(*collectionPath, *typed, w); err != nil {
+		log.Fatalf("❌ Export failed: %v", err)
+	}
+
+	if *out != "" {
+		fmt.Printf("✅ Export written to '%s'.\n", *out)
 	}
 }
@@ -1,48 +1,48 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
-)
 
-func performDatabaseGet(url string, token string) {
-	client := &http.Client{}
+	"github.com/freddd/scripts/gcpclient"
+)
 
+func performDatabaseGet(client *gcpclient.Client, url string) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		log.Printf("❌ Failed to create request: %v", err)
 		return
 	}
-
 	req.Header.Set("Accept", "application/json")
 
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-	}
-
-	resp, err := client.Do(req)
+	dual, err := client.DualCheck(req)
 	if err != nil {
 		log.Printf("An error occurred with the network request: %v", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("❌ Failed to read response body: %v", err)
-		return
+	fmt.Println("\n==================== UNAUTHENTICATED GET =====================")
+	reportDatabaseResult(dual.Anonymous, false)
+
+	fmt.Println("\n==================== AUTHENTICATED GET =======================")
+	if dual.Authenticated != nil {
+		reportDatabaseResult(dual.Authenticated, true)
+	} else {
+		fmt.Println("ℹ️  No ADC credentials available. Skipping authenticated check.")
 	}
+}
 
+func reportDatabaseResult(resp *gcpclient.Response, authenticated bool) {
 	switch resp.StatusCode {
 	case http.StatusOK:
 		fmt.Println("✅ Request successful (200 OK). Database metadata retrieved.")
 		var prettyJSON any
-		if err := json.Unmarshal(body, &prettyJSON); err != nil {
+		if err := json.Unmarshal(resp.Body, &prettyJSON); err != nil {
 			log.Printf("❌ Failed to parse JSON response: %v", err)
 			return
 		}
@@ -54,16 +54,16 @@ func performDatabaseGet(url string, token string) {
 		fmt.Println(string(prettyBody))
 	case http.StatusUnauthorized, http.StatusForbidden:
 		fmt.Printf("❌ Request failed (%d Permission Denied).\n", resp.StatusCode)
-		if token == "" {
+		if !authenticated {
 			fmt.Println("   This is the expected result for an unauthenticated user, as database metadata is not public.")
 		} else {
-			fmt.Println("   The provided token may be invalid, expired, or lack the 'firestore.databases.get' permission.")
+			fmt.Println("   The ADC credentials may be invalid, expired, or lack the 'firestore.databases.get' permission.")
 		}
 	case http.StatusNotFound:
-		fmt.Printf("ℹ️  Request failed (404 Not Found). The project or database does not exist.\n")
+		fmt.Println("ℹ️  Request failed (404 Not Found). The project or database does not exist.")
 	default:
 		fmt.Printf("❌ An error occurred. Status Code: %d\n", resp.StatusCode)
-		fmt.Printf("   Response: %s\n", string(body))
+		fmt.Printf("   Response: %s\n", string(resp.Body))
 	}
 }
 
@@ -71,7 +71,7 @@ func main() {
 	apiKey := flag.String("api-key", "", "Google Cloud API key. (Required)")
 	projectID := flag.String("project-id", "", "Your Google Cloud project ID. (Required)")
 	databaseID := flag.String("database-id", "(default)", "The Firestore database ID (usually '(default)').")
-	token := flag.String("token", "", "Optional. An OAuth 2.0 bearer token for authenticated requests.")
+	verbose := flag.Bool("verbose", false, "Log every outgoing request and response status.")
 
 	flag.Parse()
 
@@ -86,13 +86,11 @@ func main() {
 	fmt.Printf("▶️  Targeting Firestore Database: '%s' in project '%s'\n", *databaseID, *projectID)
 	fmt.Printf("   API Endpoint: %s\n", url)
 
-	fmt.Println("\n" + "==================== UNAUTHENTICATED GET =====================")
-	performDatabaseGet(url, "")
-
-	fmt.Println("\n" + "==================== AUTHENTICATED GET =======================")
-	if *token != "" {
-		performDatabaseGet(url, *token)
-	} else {
-		fmt.Println("ℹ️  No --token provided. Skipping authenticated check.")
+	client, err := gcpclient.New(context.Background(), []string{"https://www.googleapis.com/auth/cloud-platform"}, *verbose)
+	if err != nil {
+		fmt.Println("ℹ️  Could not obtain ADC credentials; run 'gcloud auth application-default login' to enable the authenticated check.")
+		client = gcpclient.NewUnauthenticated(*verbose)
 	}
+
+	performDatabaseGet(client, url)
 }
@@ -0,0 +1,213 @@
+// Package gcpclient centralizes the HTTP plumbing shared by this repo's Google API
+// tools: bearer-token attachment with on-disk caching, retry with backoff, structured
+// request/response logging, and a DualCheck helper for comparing anonymous vs.
+// authenticated access to the same resource.
+package gcpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	retryInitialDelay = time.Second
+	retryMaxDelay     = 30 * time.Second
+	retryMaxAttempts  = 6
+)
+
+// Response is a fully-drained HTTP response: the body is read into memory up front so it
+// can be inspected (and, for DualCheck, compared) without the caller worrying about
+// closing or re-reading a live *http.Response.Body.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Client wraps an authenticated HTTP client for Google APIs.
+type Client struct {
+	httpClient  *http.Client
+	tokenSource oauth2.TokenSource
+	verbose     bool
+}
+
+// New builds a Client using Application Default Credentials for the given scopes. The
+// resulting token is cached on disk (encrypted) and transparently refreshed.
+func New(ctx context.Context, scopes []string, verbose bool) (*Client, error) {
+	base, err := google.DefaultTokenSource(ctx, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain Application Default Credentials: %w", err)
+	}
+
+	path, err := adcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := newCachingTokenSource(base, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{httpClient: &http.Client{}, tokenSource: cached, verbose: verbose}, nil
+}
+
+// NewUnauthenticated builds a Client with no credentials. Do returns an error; callers
+// that only need anonymous probing (or that want DualCheck to skip the authenticated leg
+// when ADC isn't available) should use this instead of New.
+func NewUnauthenticated(verbose bool) *Client {
+	return &Client{httpClient: &http.Client{}, verbose: verbose}
+}
+
+func adcPath() (string, error) {
+	if p := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); p != "" {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json"), nil
+}
+
+// Do performs req with a bearer token attached, retrying on 429/5xx with exponential
+// backoff (honoring a Retry-After header when present).
+func (c *Client) Do(req *http.Request) (*Response, error) {
+	return c.do(req, true)
+}
+
+// DoUnauthenticated performs req without attaching any credentials, with the same retry
+// behavior as Do.
+func (c *Client) DoUnauthenticated(req *http.Request) (*Response, error) {
+	return c.do(req, false)
+}
+
+// DualResult holds the outcome of probing the same request both anonymously and
+// authenticated, for comparison.
+type DualResult struct {
+	Anonymous     *Response
+	Authenticated *Response
+}
+
+// DualCheck runs req once unauthenticated and once authenticated, returning both
+// responses so the caller can diff them. req must have been built with a body type that
+// supports GetBody (e.g. bytes.NewBuffer/bytes.NewReader/strings.NewReader), since it is
+// replayed twice.
+func (c *Client) DualCheck(req *http.Request) (*DualResult, error) {
+	anon, err := c.DoUnauthenticated(req)
+	if err != nil {
+		return nil, fmt.Errorf("anonymous check failed: %w", err)
+	}
+	if c.tokenSource == nil {
+		return &DualResult{Anonymous: anon}, nil
+	}
+	auth, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("authenticated check failed: %w", err)
+	}
+	return &DualResult{Anonymous: anon, Authenticated: auth}, nil
+}
+
+func (c *Client) do(req *http.Request, authenticate bool) (*Response, error) {
+	if authenticate && c.tokenSource == nil {
+		return nil, fmt.Errorf("no credentials configured; use NewUnauthenticated only for anonymous checks")
+	}
+
+	delay := retryInitialDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= retryMaxAttempts; attempt++ {
+		attemptReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			attemptReq.Body = io.NopCloser(body)
+		}
+
+		if authenticate {
+			tok, err := c.tokenSource.Token()
+			if err != nil {
+				return nil, fmt.Errorf("failed to obtain token: %w", err)
+			}
+			attemptReq.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+		}
+
+		c.logRequest(attemptReq, authenticate, attempt)
+
+		resp, err := c.httpClient.Do(attemptReq)
+		if err != nil {
+			lastErr = err
+		} else {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", readErr)
+			}
+
+			c.logResponse(resp, len(body))
+
+			if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+				lastErr = fmt.Errorf("status %d", resp.StatusCode)
+				if attempt == retryMaxAttempts {
+					return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+				}
+				time.Sleep(retryAfterOrBackoff(resp.Header, delay))
+				delay = nextDelay(delay)
+				continue
+			}
+
+			return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+		}
+
+		if attempt == retryMaxAttempts {
+			break
+		}
+		time.Sleep(delay)
+		delay = nextDelay(delay)
+	}
+
+	return nil, fmt.Errorf("request failed after %d attempts: %w", retryMaxAttempts, lastErr)
+}
+
+func nextDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+func retryAfterOrBackoff(header http.Header, backoff time.Duration) time.Duration {
+	if ra := header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return backoff
+}
+
+func (c *Client) logRequest(req *http.Request, authenticated bool, attempt int) {
+	if !c.verbose {
+		return
+	}
+	log.Printf("→ %s %s (authenticated=%v, attempt=%d)", req.Method, req.URL, authenticated, attempt)
+}
+
+func (c *Client) logResponse(resp *http.Response, bodyLen int) {
+	if !c.verbose {
+		return
+	}
+	log.Printf("← %d %s (%d bytes)", resp.StatusCode, resp.Request.URL, bodyLen)
+}
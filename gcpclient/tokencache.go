@@ -0,0 +1,130 @@
+package gcpclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// cachingTokenSource wraps a base oauth2.TokenSource with an on-disk cache encrypted with
+// a key derived (via scrypt) from the path of the ADC credentials file. The key depends only
+// on that path string and a fixed salt, so it does not bind the cache to a machine or account.
+type cachingTokenSource struct {
+	base oauth2.TokenSource
+	key  []byte
+	path string
+
+	mu sync.Mutex
+}
+
+func newCachingTokenSource(base oauth2.TokenSource, adcPath string) (oauth2.TokenSource, error) {
+	key, err := deriveKey(adcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive token cache key: %w", err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	return &cachingTokenSource{
+		base: base,
+		key:  key,
+		path: filepath.Join(cacheDir, "gcpclient", "token.enc"),
+	}, nil
+}
+
+func deriveKey(adcPath string) ([]byte, error) {
+	return scrypt.Key([]byte(adcPath), []byte("gcpclient-token-cache-v1"), 1<<15, 8, 1, 32)
+}
+
+func (c *cachingTokenSource) Token() (*oauth2.Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tok, err := c.readCache(); err == nil && tok.Valid() {
+		return tok, nil
+	}
+
+	tok, err := c.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	// Caching is best-effort: a failure here shouldn't fail the caller, since we already
+	// have a valid token to hand back.
+	_ = c.writeCache(tok)
+	return tok, nil
+}
+
+func (c *cachingTokenSource) readCache() (*oauth2.Token, error) {
+	ciphertext, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := decrypt(c.key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var tok oauth2.Token
+	if err := json.Unmarshal(plaintext, &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+func (c *cachingTokenSource) writeCache(tok *oauth2.Token) error {
+	plaintext, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := encrypt(c.key, plaintext)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, ciphertext, 0o600)
+}
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("token cache file is corrupt")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,7 +10,27 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+const (
+	providerS3    = "s3"
+	providerGCS   = "gcs"
+	providerAzure = "azure"
+
+	// gcsDefaultChunkSize must be a multiple of 256 KiB per the GCS resumable upload spec.
+	gcsDefaultChunkSize = 8 * 1024 * 1024
+
+	retryInitialDelay = time.Second
+	retryMaxDelay     = 30 * time.Second
+	retryMaxAttempts  = 6
 )
 
 type APIRequest struct {
@@ -20,11 +41,27 @@ type APIRequest struct {
 	ACL         string `json:"acl,omitempty"`
 }
 
+// UploadPart describes one range of an S3-style multipart upload, as advertised by the
+// pre-signing API when it knows the caller intends to upload in parallel.
+type UploadPart struct {
+	PartNumber int    `json:"part_number"`
+	URL        string `json:"url"`
+	RangeStart int64  `json:"range_start"`
+	RangeEnd   int64  `json:"range_end"`
+}
+
 type APIResponse struct {
-	URL string `json:"url"`
+	URL   string       `json:"url"`
+	Parts []UploadPart `json:"parts,omitempty"`
 }
 
-func getUploadURL(apiURL, filename, folder, acl, authToken string) (string, error) {
+type uploadOptions struct {
+	Provider string
+	Resume   bool
+	Parallel int
+}
+
+func getUploadTarget(apiURL, filename, folder, acl, authToken string) (*APIResponse, error) {
 	fmt.Printf("▶️  Step 1: Requesting upload URL from '%s'...\n", apiURL)
 
 	payload := APIRequest{
@@ -44,12 +81,12 @@ func getUploadURL(apiURL, filename, folder, acl, authToken string) (string, erro
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request JSON: %w", err)
+		return nil, fmt.Errorf("failed to create request JSON: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		return "", fmt.Errorf("failed to create API request: %w", err)
+		return nil, fmt.Errorf("failed to create API request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -60,29 +97,112 @@ func getUploadURL(apiURL, filename, folder, acl, authToken string) (string, erro
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error calling API: %w", err)
+		return nil, fmt.Errorf("error calling API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned non-200 status code: %d. Response: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API returned non-200 status code: %d. Response: %s", resp.StatusCode, string(body))
 	}
 
 	var apiResp APIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("error parsing API response: %w", err)
+		return nil, fmt.Errorf("error parsing API response: %w", err)
+	}
+
+	if apiResp.URL == "" && len(apiResp.Parts) == 0 {
+		return nil, fmt.Errorf("API response did not contain a 'url' or 'parts' field")
+	}
+
+	return &apiResp, nil
+}
+
+// withInterruptHandling returns a context that is cancelled the first time the process
+// receives SIGINT, so in-flight uploads can flush their progress bar and abort cleanly
+// instead of leaving the terminal in a dirty state.
+func withInterruptHandling() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			fmt.Println("\n⚠️  Interrupted, aborting upload...")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
+	}()
+	return ctx, cancel
+}
+
+// withBackoff retries attempt on network errors and 5xx responses with exponential
+// backoff (1s initial, 30s cap, 6 attempts total).
+func withBackoff(ctx context.Context, attempt func() (*http.Response, error)) (*http.Response, error) {
+	delay := retryInitialDelay
+	var lastErr error
+
+	for i := 0; i < retryMaxAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > retryMaxDelay {
+				delay = retryMaxDelay
+			}
+		}
+
+		resp, err := attempt()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("server error: %d", resp.StatusCode)
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", retryMaxAttempts, lastErr)
+}
+
+// queryResumeOffset asks the pre-signed target how much of the file it already has, via a
+// zero-length PUT with a "bytes */total" Content-Range, and returns the offset to resume
+// from.
+func queryResumeOffset(client *http.Client, uploadURL string, size int64) (int64, error) {
+	req, err := http.NewRequest("PUT", uploadURL, nil)
+	if err != nil {
+		return 0, err
 	}
+	req.ContentLength = 0
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
 
-	if apiResp.URL == "" {
-		return "", fmt.Errorf("API response did not contain a 'url' field")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
 	}
+	defer resp.Body.Close()
 
-	return apiResp.URL, nil
+	switch resp.StatusCode {
+	case http.StatusPermanentRedirect:
+		return parseResumeOffset(resp.Header.Get("Range"), 0)
+	case http.StatusOK, http.StatusCreated:
+		return size, nil
+	case http.StatusNotFound, http.StatusGone:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unexpected status %d while probing resume offset", resp.StatusCode)
+	}
 }
 
-func uploadFile(uploadURL, filePath string) error {
-	fmt.Printf("\n▶️  Step 2: Uploading '%s' to pre-signed URL...\n", filepath.Base(filePath))
+func uploadFile(target *APIResponse, filePath string, opts uploadOptions) error {
+	fmt.Printf("\n▶️  Step 2: Uploading '%s' to pre-signed URL (provider: %s)...\n", filepath.Base(filePath), opts.Provider)
 
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -90,12 +210,159 @@ func uploadFile(uploadURL, filePath string) error {
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat '%s': %w", filePath, err)
+	}
+
+	if opts.Parallel > 1 && len(target.Parts) > 0 {
+		fmt.Printf("   - Server advertised %d parts, uploading with up to %d parallel workers...\n", len(target.Parts), opts.Parallel)
+		return uploadParallel(target.Parts, file, info.Size(), opts)
+	}
+
+	switch opts.Provider {
+	case providerGCS:
+		return uploadGCSResumable(target.URL, file, info.Size())
+	case providerAzure:
+		return uploadAzureBlob(target.URL, file, info.Size())
+	default:
+		return uploadS3(target.URL, file, info.Size(), opts)
+	}
+}
+
+func uploadS3(uploadURL string, file *os.File, size int64, opts uploadOptions) error {
+	client := &http.Client{}
+	ctx, cancel := withInterruptHandling()
+	defer cancel()
+
+	var startOffset int64
+	if opts.Resume {
+		offset, err := queryResumeOffset(client, uploadURL, size)
+		if err != nil {
+			return fmt.Errorf("failed to query resume offset: %w", err)
+		}
+		if offset >= size {
+			fmt.Println("✅ File already fully uploaded according to the server.")
+			return nil
+		}
+		if offset > 0 {
+			fmt.Printf("   - Resuming upload from byte %d of %d\n", offset, size)
+		}
+		startOffset = offset
+	}
+
+	bar := pb.Full.Start64(size)
+	bar.SetCurrent(startOffset)
+	defer bar.Finish()
+
+	resp, err := withBackoff(ctx, func() (*http.Response, error) {
+		if _, err := file.Seek(startOffset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		bar.SetCurrent(startOffset)
+		proxy := bar.NewProxyReader(file)
+
+		req, err := http.NewRequest("PUT", uploadURL, proxy)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size - startOffset
+		req.Header.Set("Content-Type", "application/zip")
+		if startOffset > 0 {
+			req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", startOffset, size-1, size))
+		}
+
+		return client.Do(req.WithContext(ctx))
+	})
+	if err != nil {
+		return fmt.Errorf("error during file upload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload failed with non-200 status code: %d. Response: %s", resp.StatusCode, string(body))
+	}
+
+	return reportSuccess(resp)
+}
+
+// uploadParallel splits the file across the ranges the API advertised in `parts` and PUTs
+// each one concurrently, bounded by opts.Parallel workers.
+func uploadParallel(parts []UploadPart, file *os.File, size int64, opts uploadOptions) error {
+	client := &http.Client{}
+	ctx, cancel := withInterruptHandling()
+	defer cancel()
+
+	bar := pb.Full.Start64(size)
+	defer bar.Finish()
+
+	sem := make(chan struct{}, opts.Parallel)
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(parts))
+
+	for _, part := range parts {
+		part := part
+		length := part.RangeEnd - part.RangeStart + 1
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := withBackoff(ctx, func() (*http.Response, error) {
+				section := io.NewSectionReader(file, part.RangeStart, length)
+				proxy := bar.NewProxyReader(section)
+
+				req, err := http.NewRequest("PUT", part.URL, proxy)
+				if err != nil {
+					return nil, err
+				}
+				req.ContentLength = length
+				req.Header.Set("Content-Type", "application/zip")
+				req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", part.RangeStart, part.RangeEnd, size))
+
+				return client.Do(req.WithContext(ctx))
+			})
+			if err != nil {
+				errCh <- fmt.Errorf("part %d failed: %w", part.PartNumber, err)
+				cancel()
+				return
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+				body, _ := io.ReadAll(resp.Body)
+				errCh <- fmt.Errorf("part %d failed with status %d: %s", part.PartNumber, resp.StatusCode, string(body))
+				cancel()
+				return
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("✅ All parts uploaded successfully!")
+	return nil
+}
+
+func uploadAzureBlob(uploadURL string, file *os.File, size int64) error {
 	req, err := http.NewRequest("PUT", uploadURL, file)
 	if err != nil {
 		return fmt.Errorf("failed to create upload request: %w", err)
 	}
 
+	req.ContentLength = size
 	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-blob-content-type", "application/zip")
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -104,11 +371,114 @@ func uploadFile(uploadURL, filePath string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed with non-200 status code: %d. Response: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("upload failed with non-2xx status code: %d. Response: %s", resp.StatusCode, string(body))
+	}
+
+	return reportSuccess(resp)
+}
+
+// uploadGCSResumable initiates a GCS resumable upload session against initURL and then
+// drives it to completion in gcsDefaultChunkSize (8 MiB) chunks, resuming from whatever
+// offset the server reports on a 308. The chunk size is fixed at compile time; no flag or
+// parameter changes it.
+func uploadGCSResumable(initURL string, file *os.File, size int64) error {
+	client := &http.Client{}
+
+	fmt.Println("   - Initiating GCS resumable upload session...")
+	initReq, err := http.NewRequest("POST", initURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create session-initiation request: %w", err)
+	}
+	initReq.Header.Set("X-Upload-Content-Type", "application/zip")
+	initReq.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	initReq.Header.Set("x-goog-content-length-range", fmt.Sprintf("0,%d", size))
+	initReq.ContentLength = 0
+
+	initResp, err := client.Do(initReq)
+	if err != nil {
+		return fmt.Errorf("error initiating resumable session: %w", err)
+	}
+	sessionURI := initResp.Header.Get("Location")
+	if sessionURI == "" {
+		var apiResp APIResponse
+		if decErr := json.NewDecoder(initResp.Body).Decode(&apiResp); decErr == nil {
+			sessionURI = apiResp.URL
+		}
+	}
+	initResp.Body.Close()
+	if sessionURI == "" {
+		return fmt.Errorf("session initiation returned no 'Location' header or session URI (status %d)", initResp.StatusCode)
+	}
+
+	fmt.Printf("   - Session URI acquired, uploading in %d MiB chunks...\n", gcsDefaultChunkSize/(1024*1024))
+
+	var offset int64
+	for offset < size {
+		end := offset + gcsDefaultChunkSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		chunkLen := end - offset + 1
+
+		chunk := make([]byte, chunkLen)
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read chunk at offset %d: %w", offset, err)
+		}
+
+		chunkReq, err := http.NewRequest("PUT", sessionURI, bytes.NewReader(chunk))
+		if err != nil {
+			return fmt.Errorf("failed to create chunk upload request: %w", err)
+		}
+		chunkReq.ContentLength = chunkLen
+		chunkReq.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, end, size))
+
+		resp, err := client.Do(chunkReq)
+		if err != nil {
+			return fmt.Errorf("error uploading chunk at offset %d: %w", offset, err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusPermanentRedirect:
+			nextOffset, parseErr := parseResumeOffset(resp.Header.Get("Range"), offset+chunkLen)
+			resp.Body.Close()
+			if parseErr != nil {
+				return fmt.Errorf("failed to parse Range header for resume: %w", parseErr)
+			}
+			offset = nextOffset
+		case http.StatusOK, http.StatusCreated:
+			defer resp.Body.Close()
+			return reportSuccess(resp)
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("chunk upload failed with status %d. Response: %s", resp.StatusCode, string(body))
+		}
 	}
 
+	return fmt.Errorf("resumable upload loop ended without a final 200/201 response")
+}
+
+// parseResumeOffset parses a "Range: bytes=0-X" response header and returns X+1, falling
+// back to fallback if the header is absent (some proxies omit it when the server has
+// already received everything up to the requested chunk).
+func parseResumeOffset(rangeHeader string, fallback int64) (int64, error) {
+	if rangeHeader == "" {
+		return fallback, nil
+	}
+	parts := strings.SplitN(rangeHeader, "-", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("unrecognized Range header: %q", rangeHeader)
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unrecognized Range header: %q", rangeHeader)
+	}
+	return last + 1, nil
+}
+
+func reportSuccess(resp *http.Response) error {
 	fmt.Printf("✅ File uploaded successfully!\n")
 	fmt.Printf("   - Status Code: %d\n", resp.StatusCode)
 	body, _ := io.ReadAll(resp.Body)
@@ -116,7 +486,6 @@ func uploadFile(uploadURL, filePath string) error {
 		fmt.Println("   - Server Response:")
 		fmt.Println(string(body))
 	}
-
 	return nil
 }
 
@@ -126,6 +495,9 @@ func main() {
 	folder := flag.String("folder", "", "Optional. The target folder/directory for the upload.")
 	acl := flag.String("acl", "", "Optional. The access control list (ACL) permissions for the uploaded file.")
 	token := flag.String("token", "", "Optional. An OAuth 2.0 bearer token for authenticating with your API.")
+	provider := flag.String("provider", providerS3, "The storage backend the pre-signed URL targets: s3, gcs, or azure.")
+	resume := flag.Bool("resume", false, "Probe the pre-signed target for already-uploaded bytes and resume from there.")
+	parallel := flag.Int("parallel", 1, "Upload this many ranges concurrently when the API advertises multipart 'parts'.")
 	flag.Parse()
 
 	if *apiURL == "" || *filePath == "" {
@@ -134,13 +506,31 @@ func main() {
 		os.Exit(1)
 	}
 
+	switch *provider {
+	case providerS3, providerGCS, providerAzure:
+	default:
+		fmt.Printf("❌ Unknown --provider %q. Must be one of: s3, gcs, azure.\n", *provider)
+		os.Exit(1)
+	}
+
+	if *parallel < 1 {
+		fmt.Println("❌ --parallel must be at least 1.")
+		os.Exit(1)
+	}
+
 	filename := filepath.Base(*filePath)
-	uploadURL, err := getUploadURL(*apiURL, filename, *folder, *acl, *token)
+	target, err := getUploadTarget(*apiURL, filename, *folder, *acl, *token)
 	if err != nil {
 		log.Fatalf("\nWorkflow failed at Step 1. Reason: %v", err)
 	}
 
-	if err := uploadFile(uploadURL, *filePath); err != nil {
+	opts := uploadOptions{
+		Provider: *provider,
+		Resume:   *resume,
+		Parallel: *parallel,
+	}
+
+	if err := uploadFile(target, *filePath, opts); err != nil {
 		log.Fatalf("\nWorkflow failed at Step 2. Reason: %v", err)
 	}
 }
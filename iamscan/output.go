@@ -0,0 +1,123 @@
+package iamscan
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// WriteCSV writes one row per result: target coordinates, the publicly-exposed
+// permissions (semicolon-separated), and any scan error.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if err := cw.Write([]string{"kind", "project", "location", "name", "publicly_exposed", "error"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := []string{
+			r.Target.Kind,
+			r.Target.Project,
+			r.Target.Location,
+			r.Target.Name,
+			strings.Join(r.PubliclyExposed, ";"),
+			r.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sarifLog is a minimal SARIF 2.1.0 document: one rule per resource kind, one result per
+// publicly-exposed permission, so findings can feed straight into a security dashboard.
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []sarifRun  `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []sarifResult  `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID  string          `json:"ruleId"`
+	Level   string          `json:"level"`
+	Message sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes one SARIF result per publicly-exposed permission found across all
+// scanned targets.
+func WriteSARIF(w io.Writer, results []Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: "iamscan", Version: "1.0.0"}},
+	}
+
+	for _, r := range results {
+		for _, permission := range r.PubliclyExposed {
+			run.Results = append(run.Results, sarifResult{
+				RuleID: fmt.Sprintf("iamscan/%s/public-permission", r.Target.Kind),
+				Level:  "error",
+				Message: sarifMessage{
+					Text: fmt.Sprintf("%s grants %q to unauthenticated callers", r.Target.Name, permission),
+				},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{
+							URI: fmt.Sprintf("%s/%s/%s", r.Target.Kind, r.Target.Project, r.Target.Name),
+						},
+					},
+				}},
+			})
+		}
+	}
+
+	doc := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,64 @@
+package iamscan
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed permissions.json
+var registryData []byte
+
+// ResourceDef describes how to build a testIamPermissions request for one resource kind:
+// the URL template (with {project}/{location}/{name} placeholders) and the candidate
+// permission set to probe.
+type ResourceDef struct {
+	URLTemplate string   `json:"url_template"`
+	Permissions []string `json:"permissions"`
+}
+
+var registry map[string]ResourceDef
+
+func init() {
+	if err := json.Unmarshal(registryData, &registry); err != nil {
+		panic(fmt.Sprintf("iamscan: bundled permissions.json is invalid: %v", err))
+	}
+}
+
+// Target identifies a single GCP resource to probe.
+type Target struct {
+	Kind     string `json:"kind"`
+	Project  string `json:"project"`
+	Location string `json:"location,omitempty"`
+	Name     string `json:"name"`
+}
+
+func (t Target) resourceDef() (ResourceDef, error) {
+	def, ok := registry[t.Kind]
+	if !ok {
+		return ResourceDef{}, fmt.Errorf("unknown resource kind %q", t.Kind)
+	}
+	return def, nil
+}
+
+func (t Target) url() (string, error) {
+	def, err := t.resourceDef()
+	if err != nil {
+		return "", err
+	}
+	url := def.URLTemplate
+	url = strings.ReplaceAll(url, "{project}", t.Project)
+	url = strings.ReplaceAll(url, "{location}", t.Location)
+	url = strings.ReplaceAll(url, "{name}", t.Name)
+	return url, nil
+}
+
+// SupportedKinds returns the resource kinds the bundled registry knows how to scan.
+func SupportedKinds() []string {
+	kinds := make([]string, 0, len(registry))
+	for kind := range registry {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
@@ -0,0 +1,147 @@
+package iamscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/freddd/scripts/gcpclient"
+)
+
+// Result holds the outcome of probing one target both anonymously and with ADC
+// credentials, so the two can be diffed to surface publicly-exposed permissions.
+type Result struct {
+	Target               Target   `json:"target"`
+	CheckedPermissions   []string `json:"checked_permissions"`
+	AnonymousGranted     []string `json:"anonymous_granted"`
+	AuthenticatedGranted []string `json:"authenticated_granted"`
+	PubliclyExposed      []string `json:"publicly_exposed"`
+	Error                string   `json:"error,omitempty"`
+}
+
+// Scanner runs testIamPermissions checks across a set of targets using a bounded worker
+// pool, probing each resource both anonymously and with ADC credentials. Every permission
+// granted to the anonymous caller is reported as publicly exposed.
+type Scanner struct {
+	Client  *gcpclient.Client
+	Workers int
+}
+
+// NewScanner builds a Scanner. workers is clamped to at least 1.
+func NewScanner(client *gcpclient.Client, workers int) *Scanner {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Scanner{Client: client, Workers: workers}
+}
+
+// LoadTargets reads a JSON array of {kind, project, location, name} objects from path.
+func LoadTargets(path string) ([]Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resources file: %w", err)
+	}
+	var targets []Target
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("failed to parse resources file: %w", err)
+	}
+	return targets, nil
+}
+
+// Scan probes every target concurrently, bounded by s.Workers, and returns one Result per
+// target in the same order they were given.
+func (s *Scanner) Scan(targets []Target) []Result {
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, s.Workers)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target Target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.scanOne(target)
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+func (s *Scanner) scanOne(target Target) Result {
+	result := Result{Target: target}
+
+	def, err := target.resourceDef()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.CheckedPermissions = def.Permissions
+
+	url, err := target.url()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	payload, err := json.Marshal(struct {
+		Permissions []string `json:"permissions"`
+	}{Permissions: def.Permissions})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	dual, err := s.Client.DualCheck(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if granted, err := parseGrantedPermissions(dual.Anonymous); err != nil {
+		result.Error = fmt.Sprintf("anonymous check failed: %v", err)
+	} else {
+		result.AnonymousGranted = granted
+	}
+
+	if dual.Authenticated != nil {
+		if granted, err := parseGrantedPermissions(dual.Authenticated); err != nil {
+			if result.Error == "" {
+				result.Error = fmt.Sprintf("authenticated check failed: %v", err)
+			}
+		} else {
+			result.AuthenticatedGranted = granted
+		}
+	}
+
+	result.PubliclyExposed = result.AnonymousGranted
+	return result
+}
+
+func parseGrantedPermissions(resp *gcpclient.Response) ([]string, error) {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		// Expected for unauthenticated callers on a non-public resource.
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var parsed struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.Unmarshal(resp.Body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+	return parsed.Permissions, nil
+}
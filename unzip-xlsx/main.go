@@ -0,0 +1,138 @@
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/freddd/scripts/xlsx"
+)
+
+func unzipXLSX(xlsxPath, outDir string) error {
+	r, err := zip.OpenReader(xlsxPath)
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", xlsxPath, err)
+	}
+	defer r.Close()
+
+	fmt.Printf("▶️  Unzipping '%s' into '%s'\n", xlsxPath, outDir)
+
+	sharedStrings, err := loadSharedStrings(&r.Reader)
+	if err != nil {
+		fmt.Printf("   - Warning: failed to load shared strings, sheets won't get inline comments: %v\n", err)
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		destPath := filepath.Join(outDir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", f.Name, err)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s in archive: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+		}
+
+		fmt.Printf("   - Writing '%s'...\n", f.Name)
+
+		out := data
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".rels") {
+			var pretty string
+			var formatErr error
+			if isWorksheetPart(f.Name) && sharedStrings != nil {
+				pretty, formatErr = xlsx.FormatSheetWithInlineStrings(data, sharedStrings, "  ")
+			} else {
+				pretty, formatErr = xlsx.PrettyXML(data, "  ")
+			}
+			if formatErr != nil {
+				fmt.Printf("   - Warning: failed to canonicalize %s, writing raw bytes: %v\n", f.Name, formatErr)
+			} else {
+				out = []byte(pretty)
+			}
+		}
+
+		if err := os.WriteFile(destPath, out, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", destPath, err)
+		}
+	}
+
+	fmt.Printf("\n✅ Success! Unzipped into '%s'\n", outDir)
+	return nil
+}
+
+func isWorksheetPart(name string) bool {
+	return strings.HasPrefix(name, "xl/worksheets/sheet") && strings.HasSuffix(name, ".xml")
+}
+
+func loadSharedStrings(r *zip.Reader) ([]string, error) {
+	for _, f := range r.File {
+		if f.Name != "xl/sharedStrings.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, err
+		}
+		return xlsx.ParseSharedStrings(data)
+	}
+	return nil, nil
+}
+
+func main() {
+	inputXlsx := flag.String("in", "", "The path to the .xlsx file to unzip. (Required)")
+	outputDir := flag.String("out", "", "The directory to unzip the XLSX contents into. (Required)")
+	roundtripCheck := flag.Bool("roundtrip-check", false, "Rebuild the unzipped directory and diff it against the original to confirm nothing but whitespace/ordering changed.")
+	flag.Parse()
+
+	if *inputXlsx == "" || *outputDir == "" {
+		fmt.Println("❌ Missing required flags: -in and -out are required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := unzipXLSX(*inputXlsx, *outputDir); err != nil {
+		log.Fatalf("\n❌ An unexpected error occurred: %v", err)
+	}
+
+	if *roundtripCheck {
+		fmt.Println("\n▶️  Running round-trip check...")
+		rebuiltPath := filepath.Join(os.TempDir(), "roundtrip-check.xlsx")
+		if err := xlsx.CreateFromDir(*outputDir, rebuiltPath, 0, -1); err != nil {
+			log.Fatalf("❌ Round-trip rebuild failed: %v", err)
+		}
+		defer os.Remove(rebuiltPath)
+
+		diffs, err := xlsx.Diff(*inputXlsx, rebuiltPath)
+		if err != nil {
+			log.Fatalf("❌ Round-trip diff failed: %v", err)
+		}
+		if len(diffs) == 0 {
+			fmt.Println("✅ Round-trip check passed: rebuilding the unzipped directory reproduces an Excel-equivalent archive.")
+		} else {
+			fmt.Printf("⚠️  Round-trip check found %d difference(s):\n", len(diffs))
+			for _, d := range diffs {
+				fmt.Printf("   - %s\n", d)
+			}
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CanonicalizeXML re-serializes XML with attributes sorted and insignificant whitespace
+// between elements dropped, so two semantically-identical documents compare equal.
+// Comments are dropped entirely since they carry no OOXML schema meaning. Element and
+// attribute names are read with RawToken so namespace prefixes (e.g. "r:id", "mc:Ignorable")
+// survive verbatim instead of being resolved away, since OOXML parts routinely depend on
+// them and two documents that differ only in prefix are not actually equivalent.
+func CanonicalizeXML(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+
+	var open tagStack
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = open.push(t.Name, preservesWhitespace(t.Name, t.Attr, open.preserving()))
+			out.WriteByte('<')
+			out.WriteString(qualifiedName(t.Name))
+			for _, a := range sortedAttrs(t.Attr) {
+				fmt.Fprintf(&out, ` %s=%q`, qualifiedName(a.Name), a.Value)
+			}
+			out.WriteByte('>')
+		case xml.EndElement:
+			if open, _, err = open.pop(t.Name); err != nil {
+				return "", err
+			}
+			out.WriteString("</")
+			out.WriteString(qualifiedName(t.Name))
+			out.WriteByte('>')
+		case xml.CharData:
+			if open.preserving() {
+				out.WriteString(string(t))
+			} else {
+				out.WriteString(strings.TrimSpace(string(t)))
+			}
+		}
+	}
+	if err := open.requireEmpty(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// preservesWhitespace reports whether text directly inside an element named by name, with
+// attrs and the given parent context, must be kept byte-for-byte. An explicit xml:space
+// wins outright; otherwise <t> (shared-string/rich-text runs) and <v> (cell values) always
+// carry significant whitespace, and anything else inherits its parent's xml:space like the
+// XML spec says it should.
+func preservesWhitespace(name xml.Name, attrs []xml.Attr, parentPreserve bool) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "xml" && a.Name.Local == "space" {
+			return a.Value == "preserve"
+		}
+	}
+	return name.Local == "t" || name.Local == "v" || parentPreserve
+}
+
+// qualifiedName renders an xml.Name with its original prefix. RawToken leaves namespace
+// prefixes unresolved in Name.Space (rather than translating them to a URL), so this is
+// just the prefix and local name stitched back together.
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// tagStack tracks open elements so callers that read with RawToken (which, unlike Token,
+// does not verify that start and end elements match) can still catch malformed XML rather
+// than silently reproducing it. It also carries each element's resolved xml:space so
+// callers know, at any point in the document, whether the text they're currently inside
+// must be preserved verbatim.
+type tagStack []tagEntry
+
+type tagEntry struct {
+	name     xml.Name
+	preserve bool
+}
+
+func (s tagStack) push(name xml.Name, preserve bool) tagStack {
+	return append(s, tagEntry{name: name, preserve: preserve})
+}
+
+func (s tagStack) pop(end xml.Name) (tagStack, tagEntry, error) {
+	if len(s) == 0 {
+		return s, tagEntry{}, fmt.Errorf("xml: unexpected end element </%s>", qualifiedName(end))
+	}
+	top := s[len(s)-1]
+	if top.name != end {
+		return s, tagEntry{}, fmt.Errorf("xml: element <%s> closed by </%s>", qualifiedName(top.name), qualifiedName(end))
+	}
+	return s[:len(s)-1], top, nil
+}
+
+func (s tagStack) requireEmpty() error {
+	if len(s) != 0 {
+		return fmt.Errorf("xml: unexpected EOF, unclosed element <%s>", qualifiedName(s[0].name))
+	}
+	return nil
+}
+
+// preserving reports whether the innermost currently-open element carries significant
+// whitespace, per preservesWhitespace.
+func (s tagStack) preserving() bool {
+	return len(s) > 0 && s[len(s)-1].preserve
+}
+
+func sortedAttrs(attrs []xml.Attr) []xml.Attr {
+	out := append([]xml.Attr(nil), attrs...)
+	sort.Slice(out, func(i, j int) bool {
+		return qualifiedName(out[i].Name) < qualifiedName(out[j].Name)
+	})
+	return out
+}
+
+// Diff compares every part of rebuiltPath against originalPath after canonicalizing any
+// XML parts, and returns a human-readable list of differences.
+func Diff(originalPath, rebuiltPath string) ([]string, error) {
+	original, err := zip.OpenReader(originalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open original '%s': %w", originalPath, err)
+	}
+	defer original.Close()
+
+	rebuilt, err := zip.OpenReader(rebuiltPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rebuilt '%s': %w", rebuiltPath, err)
+	}
+	defer rebuilt.Close()
+
+	originalParts := make(map[string][]byte, len(original.File))
+	for _, f := range original.File {
+		data, err := readZipEntry(&original.Reader, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		originalParts[f.Name] = data
+	}
+
+	rebuiltParts := make(map[string][]byte, len(rebuilt.File))
+	for _, f := range rebuilt.File {
+		data, err := readZipEntry(&rebuilt.Reader, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		rebuiltParts[f.Name] = data
+	}
+
+	var diffs []string
+	for name, origData := range originalParts {
+		newData, ok := rebuiltParts[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("missing in rebuilt archive: %s", name))
+			continue
+		}
+		if isXMLPart(name) {
+			origCanon, err := CanonicalizeXML(origData)
+			if err != nil {
+				diffs = append(diffs, fmt.Sprintf("%s: failed to canonicalize original: %v", name, err))
+				continue
+			}
+			newCanon, err := CanonicalizeXML(newData)
+			if err != nil {
+				diffs = append(diffs, fmt.Sprintf("%s: failed to canonicalize rebuilt: %v", name, err))
+				continue
+			}
+			if origCanon != newCanon {
+				diffs = append(diffs, fmt.Sprintf("%s: XML content differs", name))
+			}
+		} else if !bytes.Equal(origData, newData) {
+			diffs = append(diffs, fmt.Sprintf("%s: binary content differs", name))
+		}
+	}
+	for name := range rebuiltParts {
+		if _, ok := originalParts[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("added in rebuilt archive: %s", name))
+		}
+	}
+
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+func isXMLPart(name string) bool {
+	return strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".rels")
+}
@@ -0,0 +1,234 @@
+package xlsx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func writeIndent(out *bytes.Buffer, indent string, depth int) {
+	for i := 0; i < depth; i++ {
+		out.WriteString(indent)
+	}
+}
+
+// PrettyXML re-serializes an XML part with sorted attributes, consistent indentation and
+// LF line endings, so the result is practical to hand-edit and diff. It reads tokens with
+// RawToken so namespace prefixes (e.g. "r:id", "mc:Ignorable") are preserved verbatim
+// instead of being resolved away, since OOXML parts routinely depend on them and a rebuild
+// that drops them produces an archive Excel won't open.
+func PrettyXML(data []byte, indent string) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	depth := 0
+
+	var open tagStack
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			preserve := preservesWhitespace(t.Name, t.Attr, open.preserving())
+			open = open.push(t.Name, preserve)
+			writeIndent(&out, indent, depth)
+			out.WriteByte('<')
+			out.WriteString(qualifiedName(t.Name))
+			for _, a := range sortedAttrs(t.Attr) {
+				fmt.Fprintf(&out, " %s=%q", qualifiedName(a.Name), a.Value)
+			}
+			out.WriteByte('>')
+			if !preserve {
+				out.WriteByte('\n')
+			}
+			depth++
+		case xml.EndElement:
+			var closed tagEntry
+			if open, closed, err = open.pop(t.Name); err != nil {
+				return "", err
+			}
+			depth--
+			if !closed.preserve {
+				writeIndent(&out, indent, depth)
+			}
+			out.WriteString("</")
+			out.WriteString(qualifiedName(t.Name))
+			out.WriteString(">\n")
+		case xml.CharData:
+			if open.preserving() {
+				out.WriteString(string(t))
+			} else if text := strings.TrimSpace(string(t)); text != "" {
+				writeIndent(&out, indent, depth)
+				out.WriteString(text)
+				out.WriteString("\n")
+			}
+		case xml.Comment:
+			writeIndent(&out, indent, depth)
+			out.WriteString("<!--")
+			out.Write(t)
+			out.WriteString("-->\n")
+		}
+	}
+	if err := open.requireEmpty(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// ParseSharedStrings extracts the plain-text value of every <si> entry in
+// xl/sharedStrings.xml, in order, concatenating across rich-text <r><t> runs.
+func ParseSharedStrings(data []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var result []string
+	var current strings.Builder
+	inSI, inT := false, false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				inSI = true
+				current.Reset()
+			case "t":
+				inT = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inT = false
+			case "si":
+				inSI = false
+				result = append(result, current.String())
+			}
+		case xml.CharData:
+			if inSI && inT {
+				current.Write(t)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// escapeComment keeps a value safe to embed in an XML comment: "--" is not allowed inside
+// one.
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, "--", "- -")
+}
+
+// FormatSheetWithInlineStrings pretty-prints a worksheet XML part the same way PrettyXML
+// does, but additionally inlines the resolved text of every shared-string cell (<c t="s">)
+// as an XML comment right after its <v>, so the sheet is practical to hand-edit without
+// cross-referencing sharedStrings.xml.
+func FormatSheetWithInlineStrings(data []byte, sharedStrings []string, indent string) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	depth := 0
+	currentCellType := ""
+	inV := false
+	var vText strings.Builder
+
+	var open tagStack
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			preserve := preservesWhitespace(t.Name, t.Attr, open.preserving())
+			open = open.push(t.Name, preserve)
+			if t.Name.Local == "c" {
+				currentCellType = ""
+				for _, a := range t.Attr {
+					if a.Name.Local == "t" {
+						currentCellType = a.Value
+					}
+				}
+			}
+			if t.Name.Local == "v" {
+				inV = true
+				vText.Reset()
+			}
+
+			writeIndent(&out, indent, depth)
+			out.WriteByte('<')
+			out.WriteString(qualifiedName(t.Name))
+			for _, a := range sortedAttrs(t.Attr) {
+				fmt.Fprintf(&out, " %s=%q", qualifiedName(a.Name), a.Value)
+			}
+			out.WriteByte('>')
+			if !preserve {
+				out.WriteByte('\n')
+			}
+			depth++
+		case xml.EndElement:
+			var closed tagEntry
+			if open, closed, err = open.pop(t.Name); err != nil {
+				return "", err
+			}
+			depth--
+
+			if !closed.preserve {
+				writeIndent(&out, indent, depth)
+			}
+			out.WriteString("</")
+			out.WriteString(qualifiedName(t.Name))
+			out.WriteString(">\n")
+
+			if t.Name.Local == "v" {
+				inV = false
+				if currentCellType == "s" {
+					if idx, convErr := strconv.Atoi(strings.TrimSpace(vText.String())); convErr == nil && idx >= 0 && idx < len(sharedStrings) {
+						writeIndent(&out, indent, depth)
+						out.WriteString("<!--")
+						out.WriteString(escapeComment(sharedStrings[idx]))
+						out.WriteString("-->\n")
+					}
+				}
+			}
+
+			if t.Name.Local == "c" {
+				currentCellType = ""
+			}
+		case xml.CharData:
+			if inV {
+				vText.Write(t)
+			}
+			if open.preserving() {
+				out.WriteString(string(t))
+			} else if text := strings.TrimSpace(string(t)); text != "" {
+				writeIndent(&out, indent, depth)
+				out.WriteString(text)
+				out.WriteString("\n")
+			}
+		}
+	}
+	if err := open.requireEmpty(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,147 @@
+// Package xlsx rebuilds and inspects the OOXML archive that makes up an .xlsx file,
+// shared by the rebuild-xlsx and unzip-xlsx commands.
+package xlsx
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// partRank orders well-known OOXML parts the way Excel itself writes them; everything
+// else sorts alphabetically after them so the archive is byte-reproducible across runs.
+func partRank(relPath string) int {
+	switch relPath {
+	case "[Content_Types].xml":
+		return 0
+	case "_rels/.rels":
+		return 1
+	case "xl/workbook.xml":
+		return 2
+	default:
+		return 3
+	}
+}
+
+func sortedEntries(dirPath string) ([]string, error) {
+	var entries []string
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Base(path) == "mimetype" {
+			return nil
+		}
+		relPath, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		ri, rj := partRank(entries[i]), partRank(entries[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return entries[i] < entries[j]
+	})
+	return entries, nil
+}
+
+// CreateFromDir rebuilds an .xlsx at xlsxPath from the unzipped contents of dirPath.
+// Entries are written in a stable, Excel-matching order with every zip entry stamped at
+// sourceDateEpoch (pass 0 for the Unix epoch) so repeated builds are byte-reproducible.
+func CreateFromDir(dirPath, xlsxPath string, sourceDateEpoch int64, compressionLevel int) error {
+	dirInfo, err := os.Stat(dirPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("❌ Error: The directory was not found at '%s'", dirPath)
+	}
+	if !dirInfo.IsDir() {
+		return fmt.Errorf("❌ Error: The path '%s' is not a directory", dirPath)
+	}
+
+	if _, err := os.Stat(filepath.Join(dirPath, "[Content_Types].xml")); os.IsNotExist(err) {
+		fmt.Printf("⚠️  Warning: Directory '%s' might not be an unzipped XLSX file.\n", dirPath)
+		fmt.Println("   (Could not find '[Content_Types].xml'). Proceeding anyway...")
+	}
+
+	fmt.Printf("▶️  Rebuilding XLSX from directory: '%s'\n", dirPath)
+
+	xlsxFile, err := os.Create(xlsxPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer xlsxFile.Close()
+
+	zipWriter := zip.NewWriter(xlsxFile)
+	defer zipWriter.Close()
+	zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, compressionLevel)
+	})
+
+	modTime := epochTime(sourceDateEpoch)
+
+	mimetypePath := filepath.Join(dirPath, "mimetype")
+	if _, err := os.Stat(mimetypePath); !os.IsNotExist(err) {
+		fmt.Println("   - Adding 'mimetype' (uncompressed)...")
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     "mimetype",
+			Method:   zip.Store,
+			Modified: modTime,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create mimetype header in zip: %w", err)
+		}
+		mimetypeFile, err := os.Open(mimetypePath)
+		if err != nil {
+			return fmt.Errorf("failed to open mimetype file: %w", err)
+		}
+		defer mimetypeFile.Close()
+		if _, err := io.Copy(writer, mimetypeFile); err != nil {
+			return fmt.Errorf("failed to copy mimetype content: %w", err)
+		}
+	} else {
+		fmt.Println("   - Warning: 'mimetype' file not found. The output XLSX may be invalid.")
+	}
+
+	relPaths, err := sortedEntries(dirPath)
+	if err != nil {
+		return fmt.Errorf("error walking the path %s: %w", dirPath, err)
+	}
+
+	for _, relPath := range relPaths {
+		fmt.Printf("   - Adding '%s'...\n", relPath)
+
+		header := &zip.FileHeader{
+			Name:     relPath,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		}
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create entry for %s in zip: %w", relPath, err)
+		}
+
+		fileToZip, err := os.Open(filepath.Join(dirPath, relPath))
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %w", relPath, err)
+		}
+		_, err = io.Copy(writer, fileToZip)
+		fileToZip.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s into zip: %w", relPath, err)
+		}
+	}
+
+	fmt.Printf("\n✅ Success! Rebuilt XLSX file saved to '%s'\n", filepath.Base(xlsxPath))
+	return nil
+}
@@ -0,0 +1,134 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func epochTime(sourceDateEpoch int64) time.Time {
+	return time.Unix(sourceDateEpoch, 0).UTC()
+}
+
+// contentTypes mirrors [Content_Types].xml.
+type contentTypes struct {
+	XMLName   xml.Name     `xml:"Types"`
+	Defaults  []ctDefault  `xml:"Default"`
+	Overrides []ctOverride `xml:"Override"`
+}
+
+type ctDefault struct {
+	Extension   string `xml:"Extension,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+type ctOverride struct {
+	PartName    string `xml:"PartName,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+// relationships mirrors a .rels part, e.g. _rels/.rels or xl/_rels/workbook.xml.rels.
+type relationships struct {
+	XMLName      xml.Name       `xml:"Relationships"`
+	Relationship []relationship `xml:"Relationship"`
+}
+
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+// relsBaseDir returns the directory a .rels part's relative Targets are resolved against:
+// the parent of the "_rels" directory that contains it.
+func relsBaseDir(relsPath string) string {
+	dir := filepath.ToSlash(filepath.Dir(filepath.Dir(relsPath)))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func resolveRelTarget(baseDir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return filepath.ToSlash(filepath.Join(baseDir, target))
+}
+
+func readZipEntry(r *zip.Reader, name string) ([]byte, error) {
+	for _, f := range r.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %q: %w", name, err)
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+	}
+	return nil, fmt.Errorf("part %q not found in archive", name)
+}
+
+// Validate re-opens a rebuilt archive and checks that every Override PartName in
+// [Content_Types].xml and every Relationship Target in every .rels part actually exists as
+// a zip entry.
+func Validate(xlsxPath string) error {
+	r, err := zip.OpenReader(xlsxPath)
+	if err != nil {
+		return fmt.Errorf("failed to open '%s': %w", xlsxPath, err)
+	}
+	defer r.Close()
+
+	entries := make(map[string]bool, len(r.File))
+	for _, f := range r.File {
+		entries[f.Name] = true
+	}
+
+	ctData, err := readZipEntry(&r.Reader, "[Content_Types].xml")
+	if err != nil {
+		return err
+	}
+	var ct contentTypes
+	if err := xml.Unmarshal(ctData, &ct); err != nil {
+		return fmt.Errorf("failed to parse [Content_Types].xml: %w", err)
+	}
+	for _, override := range ct.Overrides {
+		partName := strings.TrimPrefix(override.PartName, "/")
+		if !entries[partName] {
+			return fmt.Errorf("[Content_Types].xml declares Override %q but no such part exists in the archive", override.PartName)
+		}
+	}
+
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".rels") {
+			continue
+		}
+		data, err := readZipEntry(&r.Reader, f.Name)
+		if err != nil {
+			return err
+		}
+		var rels relationships
+		if err := xml.Unmarshal(data, &rels); err != nil {
+			return fmt.Errorf("failed to parse %q: %w", f.Name, err)
+		}
+
+		baseDir := relsBaseDir(f.Name)
+		for _, rel := range rels.Relationship {
+			if rel.TargetMode == "External" {
+				continue
+			}
+			target := resolveRelTarget(baseDir, rel.Target)
+			if !entries[target] {
+				return fmt.Errorf("%q declares Relationship %q -> %q but no such part exists in the archive", f.Name, rel.ID, target)
+			}
+		}
+	}
+
+	return nil
+}